@@ -0,0 +1,50 @@
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+// Bundle is an OSC bundle: a time tag plus a set of Messages and/or nested
+// Bundles that should be applied together.
+type Bundle struct {
+	Timetag time.Time
+	Packets []Packet
+}
+
+// NewBundle returns a new Bundle scheduled for timetag.
+func NewBundle(timetag time.Time) *Bundle {
+	return &Bundle{Timetag: timetag}
+}
+
+// Append adds packet to the bundle.
+func (b *Bundle) Append(packet Packet) {
+	b.Packets = append(b.Packets, packet)
+}
+
+// ToByteArray serializes the bundle to the OSC binary format.
+func (b *Bundle) ToByteArray() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if _, err := writePaddedString(BundleTag, &buf); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&buf, binary.BigEndian, timeToTimetag(b.Timetag)); err != nil {
+		return nil, err
+	}
+
+	for _, packet := range b.Packets {
+		data, err := packet.ToByteArray()
+		if err != nil {
+			return nil, err
+		}
+		if err := binary.Write(&buf, binary.BigEndian, int32(len(data))); err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package osc
+
+import "net"
+
+// Client sends OSC packets to a fixed remote address. The scheme in addr
+// ("udp://", "tcp://", "tcp+slip://", "unix://" or "unix+stream://")
+// selects the transport; an address with no scheme defaults to UDP.
+type Client struct {
+	scheme scheme
+	addr   string
+	conn   net.Conn
+}
+
+// NewClient returns a new Client that sends to addr.
+func NewClient(addr string) *Client {
+	s, hostport, err := splitScheme(addr)
+	if err != nil {
+		s, hostport = schemeUDP, addr
+	}
+	return &Client{scheme: s, addr: hostport}
+}
+
+// Send serializes packet and writes it to the client's address, dialing
+// (or redialing, after a previous write failure) as needed.
+func (c *Client) Send(packet Packet) error {
+	data, err := packet.ToByteArray()
+	if err != nil {
+		return err
+	}
+
+	if c.conn == nil {
+		conn, err := net.Dial(c.scheme.network(), c.addr)
+		if err != nil {
+			return err
+		}
+		c.conn = conn
+	}
+
+	switch c.scheme {
+	case schemeTCP, schemeUnixStream:
+		err = writeLengthPrefixed(c.conn, data)
+	case schemeTCPSLIP:
+		err = writeSLIP(c.conn, data)
+	default:
+		_, err = c.conn.Write(data)
+	}
+
+	if err != nil {
+		// Drop the connection so the next Send reconnects.
+		c.conn.Close()
+		c.conn = nil
+	}
+
+	return err
+}
+
+// Close closes the client's connection, if any.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -0,0 +1,100 @@
+package osc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HandlerFunc handles an incoming OSC message.
+type HandlerFunc func(msg *Message)
+
+// oscHandler is a handler registered against an OSC address pattern, with
+// each '/'-delimited part precompiled to a matcher.
+type oscHandler struct {
+	pattern string
+	parts   []string
+	regexes []*regexp.Regexp
+	handler HandlerFunc
+}
+
+// OscDispatcher dispatches OSC packets to the handlers whose address
+// pattern matches the packet's address.
+type OscDispatcher struct {
+	handlers []*oscHandler
+}
+
+// NewOscDispatcher returns a new OscDispatcher.
+func NewOscDispatcher() *OscDispatcher {
+	return &OscDispatcher{}
+}
+
+// AddMsgHandler registers handler for address. address is an OSC address
+// pattern and may contain the full OSC pattern matching grammar ('?',
+// '*', '[abc]', '[a-z]', '[!abc]', '{foo,bar}').
+func (d *OscDispatcher) AddMsgHandler(address string, handler HandlerFunc) error {
+	if !strings.HasPrefix(address, "/") {
+		return fmt.Errorf("osc: address %q must start with '/'", address)
+	}
+
+	parts := strings.Split(address, "/")[1:]
+	regexes := make([]*regexp.Regexp, len(parts))
+	for i, part := range parts {
+		re, err := compilePatternPart(part)
+		if err != nil {
+			return err
+		}
+		regexes[i] = re
+	}
+
+	d.handlers = append(d.handlers, &oscHandler{
+		pattern: address,
+		parts:   parts,
+		regexes: regexes,
+		handler: handler,
+	})
+	return nil
+}
+
+// Dispatch delivers packet to every handler whose pattern matches, in
+// registration order. Bundles are expanded and their contents dispatched
+// in order.
+func (d *OscDispatcher) Dispatch(packet Packet) {
+	switch p := packet.(type) {
+	case *Message:
+		for _, h := range d.handlers {
+			if h.matches(p.address) {
+				h.handler(p)
+			}
+		}
+	case *Bundle:
+		for _, inner := range p.Packets {
+			d.Dispatch(inner)
+		}
+	}
+}
+
+// matches reports whether address matches h's registered pattern. Since
+// the incoming address may itself be a pattern (per the OSC spec), a part
+// matches if either side's compiled pattern matches the other's literal
+// text.
+func (h *oscHandler) matches(address string) bool {
+	if !strings.HasPrefix(address, "/") {
+		return false
+	}
+	addrParts := strings.Split(address, "/")[1:]
+	if len(addrParts) != len(h.parts) {
+		return false
+	}
+
+	for i, addrPart := range addrParts {
+		if h.regexes[i].MatchString(addrPart) {
+			continue
+		}
+		addrRe, err := compilePatternPart(addrPart)
+		if err != nil || !addrRe.MatchString(h.parts[i]) {
+			return false
+		}
+	}
+	return true
+}
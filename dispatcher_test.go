@@ -0,0 +1,80 @@
+package osc
+
+import "testing"
+
+func TestDispatcherWildcardFanOut(t *testing.T) {
+	d := NewOscDispatcher()
+
+	var got []string
+	record := func(name string) HandlerFunc {
+		return func(msg *Message) { got = append(got, name) }
+	}
+
+	if err := d.AddMsgHandler("/synth/*/freq", record("synth-freq")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddMsgHandler("/mixer/{ch1,ch2}/gain", record("mixer-gain")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddMsgHandler("/mixer/ch3/gain", record("mixer-ch3-gain")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddMsgHandler("/[!_]*", record("no-underscore")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		address string
+		want    []string
+	}{
+		{"/synth/1/freq", []string{"synth-freq"}},
+		{"/synth/lead/freq", []string{"synth-freq"}},
+		{"/mixer/ch1/gain", []string{"mixer-gain"}},
+		{"/mixer/ch2/gain", []string{"mixer-gain"}},
+		{"/mixer/ch3/gain", []string{"mixer-ch3-gain"}},
+		{"/other", []string{"no-underscore"}},
+		{"/_hidden", nil},
+	}
+
+	for _, c := range cases {
+		got = nil
+		d.Dispatch(NewMessage(c.address))
+		if !equalStrings(got, c.want) {
+			t.Errorf("dispatch(%q) = %v, want %v", c.address, got, c.want)
+		}
+	}
+}
+
+func TestDispatcherIncomingPatternFansOutToLiteralHandlers(t *testing.T) {
+	d := NewOscDispatcher()
+
+	var got []string
+	record := func(name string) HandlerFunc {
+		return func(msg *Message) { got = append(got, name) }
+	}
+
+	if err := d.AddMsgHandler("/mixer/ch1/gain", record("ch1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddMsgHandler("/mixer/ch2/gain", record("ch2")); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Dispatch(NewMessage("/mixer/{ch1,ch2}/gain"))
+
+	if !equalStrings(got, []string{"ch1", "ch2"}) {
+		t.Errorf("got %v, want [ch1 ch2]", got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
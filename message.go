@@ -0,0 +1,173 @@
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Packet is an OSC Message or Bundle.
+type Packet interface {
+	ToByteArray() ([]byte, error)
+}
+
+// Message is an OSC message: an address pattern plus a list of arguments.
+type Message struct {
+	address   string
+	Arguments []interface{}
+}
+
+// NewMessage returns a new Message for address.
+func NewMessage(address string) *Message {
+	return &Message{address: address}
+}
+
+// Address returns the message's OSC address.
+func (msg *Message) Address() string {
+	return msg.address
+}
+
+// Append adds one or more arguments to the message.
+func (msg *Message) Append(args ...interface{}) {
+	msg.Arguments = append(msg.Arguments, args...)
+}
+
+// TypeTags returns the OSC type tag string (including the leading ',')
+// describing msg.Arguments.
+func (msg *Message) TypeTags() (string, error) {
+	tags, err := typeTagsForArgs(msg.Arguments)
+	if err != nil {
+		return "", err
+	}
+	return "," + tags, nil
+}
+
+// typeTagsForArgs returns the type tags (without a leading ',') for args,
+// wrapping nested []interface{} slices in '[' ']' per the OSC array
+// convention.
+func typeTagsForArgs(args []interface{}) (string, error) {
+	tags := ""
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case int32:
+			tags += "i"
+		case int64:
+			tags += "h"
+		case float32:
+			tags += "f"
+		case float64:
+			tags += "d"
+		case string:
+			tags += "s"
+		case Symbol:
+			tags += "S"
+		case []byte:
+			tags += "b"
+		case Timetag:
+			tags += "t"
+		case Char:
+			tags += "c"
+		case RGBA:
+			tags += "r"
+		case MIDI:
+			tags += "m"
+		case Nil:
+			tags += "N"
+		case Impulse:
+			tags += "I"
+		case bool:
+			if v {
+				tags += "T"
+			} else {
+				tags += "F"
+			}
+		case []interface{}:
+			nested, err := typeTagsForArgs(v)
+			if err != nil {
+				return "", err
+			}
+			tags += "[" + nested + "]"
+		default:
+			return "", fmt.Errorf("osc: unsupported argument type: %T", arg)
+		}
+	}
+	return tags, nil
+}
+
+// ToByteArray serializes the message to the OSC binary format.
+func (msg *Message) ToByteArray() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if _, err := writePaddedString(msg.address, &buf); err != nil {
+		return nil, err
+	}
+
+	tags, err := msg.TypeTags()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writePaddedString(tags, &buf); err != nil {
+		return nil, err
+	}
+
+	if err := writeArgs(&buf, msg.Arguments); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeArgs writes the wire representation of each argument in args to
+// buf, recursing into nested []interface{} arrays.
+func writeArgs(buf *bytes.Buffer, args []interface{}) error {
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case int32, int64, float32, float64, Timetag:
+			if err := binary.Write(buf, binary.BigEndian, v); err != nil {
+				return err
+			}
+
+		case Char:
+			if err := binary.Write(buf, binary.BigEndian, int32(v)); err != nil {
+				return err
+			}
+
+		case string:
+			if _, err := writePaddedString(v, buf); err != nil {
+				return err
+			}
+
+		case Symbol:
+			if _, err := writePaddedString(string(v), buf); err != nil {
+				return err
+			}
+
+		case []byte:
+			if err := binary.Write(buf, binary.BigEndian, int32(len(v))); err != nil {
+				return err
+			}
+			buf.Write(v)
+			if pad := padBytesNeeded(len(v)); pad > 0 {
+				buf.Write(make([]byte, pad))
+			}
+
+		case RGBA:
+			buf.Write([]byte{v.R, v.G, v.B, v.A})
+
+		case MIDI:
+			buf.Write([]byte{v.PortID, v.Status, v.Data1, v.Data2})
+
+		case bool, Nil, Impulse:
+			// These carry no argument bytes; the value is in the type tag.
+
+		case []interface{}:
+			if err := writeArgs(buf, v); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("osc: unsupported argument type: %T", arg)
+		}
+	}
+	return nil
+}
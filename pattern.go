@@ -0,0 +1,66 @@
+package osc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compilePatternPart compiles a single '/'-delimited part of an OSC
+// address pattern into a regular expression matching that part. It
+// supports the OSC pattern matching grammar: '?' (any single character),
+// '*' (any sequence of characters), '[abc]'/'[a-z]'/'[!abc]' (character
+// classes, optionally negated) and '{foo,bar}' (alternation).
+func compilePatternPart(part string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(part); {
+		switch c := part[i]; c {
+		case '*':
+			sb.WriteString(".*")
+			i++
+
+		case '?':
+			sb.WriteString(".")
+			i++
+
+		case '[':
+			end := strings.IndexByte(part[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("osc: unterminated '[' in pattern %q", part)
+			}
+			class := part[i+1 : i+end]
+			sb.WriteString("[")
+			if strings.HasPrefix(class, "!") {
+				sb.WriteString("^" + class[1:])
+			} else {
+				sb.WriteString(class)
+			}
+			sb.WriteString("]")
+			i += end + 1
+
+		case '{':
+			end := strings.IndexByte(part[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("osc: unterminated '{' in pattern %q", part)
+			}
+			alts := strings.Split(part[i+1:i+end], ",")
+			for j, alt := range alts {
+				alts[j] = regexp.QuoteMeta(alt)
+			}
+			sb.WriteString("(" + strings.Join(alts, "|") + ")")
+			i += end + 1
+
+		case ']', '}':
+			return nil, fmt.Errorf("osc: unmatched %q in pattern %q", string(c), part)
+
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+			i++
+		}
+	}
+
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
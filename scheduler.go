@@ -0,0 +1,184 @@
+package osc
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// LatePolicy controls how a scheduler handles a packet whose explicit
+// bundle time tag has already passed by the time it is scheduled.
+type LatePolicy int
+
+const (
+	// RunImmediately dispatches late packets right away, as if they had
+	// arrived with the "now" time tag. This is the default.
+	RunImmediately LatePolicy = iota
+	// DropLate silently discards late packets instead of dispatching them.
+	DropLate
+)
+
+// scheduledItem is one entry in a scheduler's min-heap, ordered by at.
+type scheduledItem struct {
+	at     time.Time
+	packet Packet
+}
+
+// schedulerHeap is a container/heap.Interface min-heap of scheduledItems.
+type schedulerHeap []*scheduledItem
+
+func (h schedulerHeap) Len() int            { return len(h) }
+func (h schedulerHeap) Less(i, j int) bool  { return h[i].at.Before(h[j].at) }
+func (h schedulerHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *schedulerHeap) Push(x interface{}) { *h = append(*h, x.(*scheduledItem)) }
+func (h *schedulerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// scheduler holds packets in a min-heap keyed by their dispatch time and
+// hands each to dispatch as soon as that time is reached. A dedicated
+// goroutine sleeps on a time.Timer set to the head of the heap.
+type scheduler struct {
+	mu        sync.Mutex
+	items     schedulerHeap
+	wake      chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+	dispatch  func(Packet)
+}
+
+// newScheduler returns a running scheduler that calls dispatch for each
+// packet as its scheduled time arrives.
+func newScheduler(dispatch func(Packet)) *scheduler {
+	s := &scheduler{
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		dispatch: dispatch,
+	}
+	go s.run()
+	return s
+}
+
+// Schedule enqueues packet to be dispatched at at.
+func (s *scheduler) Schedule(at time.Time, packet Packet) {
+	s.mu.Lock()
+	heap.Push(&s.items, &scheduledItem{at: at, packet: packet})
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the scheduler's goroutine and blocks until it has exited,
+// so a dispatch that is already in flight finishes before Close returns.
+// Packets still in the heap but not yet due are discarded. It is safe to
+// call more than once.
+func (s *scheduler) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+	<-s.stopped
+}
+
+// run waits for the next due packet, either because the timer for the
+// head of the heap fires or because Schedule pushed a new (possibly
+// earlier) head that needs a fresh timer.
+func (s *scheduler) run() {
+	defer close(s.stopped)
+
+	var timer *time.Timer
+	for {
+		s.mu.Lock()
+		empty := len(s.items) == 0
+		var wait time.Duration
+		if !empty {
+			wait = time.Until(s.items[0].at)
+		}
+		s.mu.Unlock()
+
+		if empty {
+			select {
+			case <-s.done:
+				return
+			case <-s.wake:
+				continue
+			}
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(wait)
+		} else {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+		}
+
+		select {
+		case <-s.done:
+			timer.Stop()
+			return
+		case <-s.wake:
+			continue
+		case <-timer.C:
+			s.fireDue()
+		}
+	}
+}
+
+// fireDue pops and dispatches every item whose time has arrived.
+func (s *scheduler) fireDue() {
+	now := time.Now()
+
+	var due []Packet
+	s.mu.Lock()
+	for len(s.items) > 0 && !s.items[0].at.After(now) {
+		item := heap.Pop(&s.items).(*scheduledItem)
+		due = append(due, item.packet)
+	}
+	s.mu.Unlock()
+
+	for _, packet := range due {
+		s.dispatch(packet)
+	}
+}
+
+// scheduledEntry is a leaf packet pulled out of a (possibly nested) bundle,
+// along with the deadline it inherited or was given explicitly.
+type scheduledEntry struct {
+	at       time.Time
+	explicit bool
+	packet   Packet
+}
+
+// flattenPacket walks packet, which may be a Bundle nested arbitrarily
+// deep, and returns one scheduledEntry per non-bundle leaf packet.
+// A nested Bundle's own time tag overrides the deadline (and is marked
+// explicit) for everything beneath it; the "immediate" time tag (the
+// zero time.Time) leaves the parent's deadline in place.
+func flattenPacket(packet Packet, parentDeadline time.Time, parentExplicit bool, clockSkew time.Duration) []scheduledEntry {
+	bundle, ok := packet.(*Bundle)
+	if !ok {
+		return []scheduledEntry{{at: parentDeadline, explicit: parentExplicit, packet: packet}}
+	}
+
+	deadline, explicit := parentDeadline, parentExplicit
+	if !bundle.Timetag.IsZero() {
+		deadline, explicit = bundle.Timetag.Add(clockSkew), true
+	}
+
+	var entries []scheduledEntry
+	for _, inner := range bundle.Packets {
+		entries = append(entries, flattenPacket(inner, deadline, explicit, clockSkew)...)
+	}
+	return entries
+}
@@ -0,0 +1,115 @@
+package osc
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSchedulerDispatchesAtDeadline(t *testing.T) {
+	var mu sync.Mutex
+	var dispatched time.Time
+
+	done := make(chan struct{})
+	s := newScheduler(func(packet Packet) {
+		mu.Lock()
+		dispatched = time.Now()
+		mu.Unlock()
+		close(done)
+	})
+	defer s.Close()
+
+	sent := time.Now()
+	deadline := sent.Add(200 * time.Millisecond)
+	s.Schedule(deadline, NewMessage("/address/test"))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("packet was not dispatched")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if delta := dispatched.Sub(deadline); delta < -20*time.Millisecond || delta > 100*time.Millisecond {
+		t.Errorf("dispatched %v from deadline, want within tolerance", delta)
+	}
+}
+
+func TestSchedulerDispatchesImmediateInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+
+	s := newScheduler(func(packet Packet) {
+		mu.Lock()
+		order = append(order, packet.(*Message).Address())
+		mu.Unlock()
+	})
+	defer s.Close()
+
+	now := time.Now()
+	s.Schedule(now, NewMessage("/first"))
+	s.Schedule(now, NewMessage("/second"))
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "/first" || order[1] != "/second" {
+		t.Errorf("got dispatch order %v, want [/first /second]", order)
+	}
+}
+
+func TestFlattenPacketNonBundle(t *testing.T) {
+	msg := NewMessage("/address/test")
+	deadline := time.Now()
+
+	entries := flattenPacket(msg, deadline, false, 0)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if entries[0].packet != Packet(msg) || entries[0].explicit {
+		t.Errorf("got %+v, want immediate entry wrapping msg", entries[0])
+	}
+}
+
+func TestFlattenPacketNestedBundleOverridesDeadline(t *testing.T) {
+	outer := NewBundle(time.Time{})
+	inner := NewBundle(time.Unix(1700000000, 0))
+	innerMsg := NewMessage("/inner")
+	inner.Append(innerMsg)
+	outerMsg := NewMessage("/outer")
+	outer.Append(outerMsg)
+	outer.Append(inner)
+
+	parentDeadline := time.Unix(1600000000, 0)
+	entries := flattenPacket(outer, parentDeadline, true, 5*time.Second)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	if entries[0].packet != Packet(outerMsg) || !entries[0].at.Equal(parentDeadline) {
+		t.Errorf("got %+v, want outer message to inherit parent deadline", entries[0])
+	}
+
+	want := inner.Timetag.Add(5 * time.Second)
+	if entries[1].packet != Packet(innerMsg) || !entries[1].at.Equal(want) || !entries[1].explicit {
+		t.Errorf("got %+v, want inner message deadline %v with clock skew applied", entries[1], want)
+	}
+}
+
+func TestSchedulerCloseStopsDispatch(t *testing.T) {
+	dispatched := make(chan struct{}, 1)
+	s := newScheduler(func(packet Packet) {
+		dispatched <- struct{}{}
+	})
+
+	s.Close()
+	s.Schedule(time.Now(), NewMessage("/address/test"))
+
+	select {
+	case <-dispatched:
+		t.Error("expected no dispatch after Close")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -3,13 +3,15 @@ package osc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"net"
-	"sync/atomic"
+	"os"
+	"sync"
 	"time"
-	"unsafe"
 )
 
 // Common errors.
@@ -20,28 +22,120 @@ var (
 	ErrInvalidTypeTag = errors.New("invalid type tag")
 )
 
+// maxUDPPacketSize is the largest value of the 16-bit UDP length field,
+// so no UDP datagram payload can exceed it; it sizes the read buffer for
+// the UDP scheme.
+const maxUDPPacketSize = 65535
+
+// maxUnixPacketSize sizes the read buffer for Unix SOCK_DGRAM sockets,
+// which aren't limited by IP/UDP framing the way UDP datagrams are.
+const maxUnixPacketSize = 1 << 20 // 1 MiB
+
 // Server is an OSC server.
 type Server struct {
 	Address     string         // Address is the listening address.
-	Listening   chan struct{}  // Listening is a channel used to indicate when the server is running.
 	readTimeout time.Duration  // readTimeout is the timeout for reading from a connection.
 	dispatcher  *OscDispatcher // Dispatcher that dispatches OSC packets/messages.
-	running     bool           // Flag to store if the server is running or not.
-	conn        *net.UDPConn   // conn is a UDP connection object.
+	conn        net.PacketConn // conn is the connection for the datagram schemes (UDP, Unix SOCK_DGRAM).
+
+	scheme   scheme       // scheme is the transport/framing selected by Address.
+	listener net.Listener // listener accepts connections for the stream-based schemes.
+	streamWg sync.WaitGroup
+
+	scheduler  *scheduler    // scheduler runs bundles at their time tag.
+	clockSkew  time.Duration // clockSkew is added to incoming time tags before scheduling.
+	latePolicy LatePolicy    // latePolicy governs packets whose time tag has already passed.
+
+	socketMode os.FileMode // socketMode is applied to a Unix domain socket file, if non-zero.
+	unixPath   string      // unixPath is the socket file to remove on Close, for the Unix schemes.
+
+	mu        sync.Mutex    // mu guards running.
+	running   bool          // running reports whether Listen or Serve has been called.
+	ready     chan struct{} // ready is closed, exactly once, when the server starts accepting packets.
+	readyOnce sync.Once
+}
+
+// SetSocketMode sets the filesystem permission bits applied to a Unix
+// domain socket file created by the server (schemes "unix://" and
+// "unix+stream://"). It has no effect for other schemes.
+func (self *Server) SetSocketMode(mode os.FileMode) {
+	self.socketMode = mode
+}
+
+// SetClockSkew sets a duration added to every incoming bundle time tag
+// before it is scheduled, to compensate for a clock offset between this
+// server and the clients sending it bundles.
+func (self *Server) SetClockSkew(d time.Duration) {
+	self.clockSkew = d
+}
+
+// SetLatePolicy sets how the server handles a bundle whose time tag has
+// already passed by the time it is scheduled. The default is
+// RunImmediately.
+func (self *Server) SetLatePolicy(policy LatePolicy) {
+	self.latePolicy = policy
 }
 
-// NewServer returns a new OSC Server.
+// NewServer returns a new OSC Server. addr may be prefixed with a scheme
+// ("udp://", "tcp://", "tcp+slip://", "unix://" or "unix+stream://") to
+// select the transport; an address with no scheme defaults to UDP. For
+// the Unix schemes, addr is a socket file path, e.g. "unix:///tmp/osc.sock".
 func NewServer(addr string) (*Server, error) {
+	s, hostport, err := splitScheme(addr)
+	if err != nil {
+		return nil, err
+	}
 	return &Server{
-		Address:     addr,
-		Listening:   make(chan struct{}),
+		Address:     hostport,
+		scheme:      s,
+		ready:       make(chan struct{}),
 		readTimeout: 0,
 		dispatcher:  NewOscDispatcher(),
 	}, nil
 }
 
-// connect initializes the server's connection.
+// Ready returns a channel that is closed once the server has started
+// listening and is ready to accept packets. Unlike the old
+// send-on-unbuffered-channel handshake, it may be observed by any number
+// of callers.
+func (self *Server) Ready() <-chan struct{} {
+	return self.ready
+}
+
+// markReady closes ready, exactly once, so repeated calls (from Listen,
+// Serve's UDP and stream paths) are safe.
+func (self *Server) markReady() {
+	self.readyOnce.Do(func() { close(self.ready) })
+}
+
+// start reports whether the server was already running and, if not,
+// marks it running. It is the synchronized replacement for the old
+// unguarded running bool.
+func (self *Server) start() bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.running {
+		return true
+	}
+	self.running = true
+	return false
+}
+
+// connect initializes the server's connection or listener, according to
+// its scheme.
 func (self *Server) connect() error {
+	switch self.scheme {
+	case schemeUnix:
+		return self.connectUnixgram()
+	case schemeUDP:
+		return self.connectUDP()
+	default:
+		return self.connectStream()
+	}
+}
+
+// connectUDP opens the server's UDP listening connection.
+func (self *Server) connectUDP() error {
 	addr, err := net.ResolveUDPAddr("udp", self.Address)
 	if err != nil {
 		return err
@@ -52,20 +146,125 @@ func (self *Server) connect() error {
 		return err
 	}
 
-	dest := (*unsafe.Pointer)(unsafe.Pointer(&self.conn))
-	if !atomic.CompareAndSwapPointer(dest, unsafe.Pointer(self.conn), unsafe.Pointer(conn)) {
-		return errors.New("could not initialize connection")
+	self.conn = conn
+	return nil
+}
+
+// connectUnixgram opens a Unix SOCK_DGRAM socket at Address, clearing away
+// a stale socket file left behind by a previous, uncleanly terminated
+// server and applying SocketMode if one was set.
+func (self *Server) connectUnixgram() error {
+	if err := removeStaleUnixSocket(self.Address); err != nil {
+		return err
+	}
+
+	addr, err := net.ResolveUnixAddr(self.scheme.network(), self.Address)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenUnixgram(self.scheme.network(), addr)
+	if err != nil {
+		return err
+	}
+
+	self.conn = conn
+	self.unixPath = self.Address
+	if err := self.chmodSocket(); err != nil {
+		conn.Close()
+		return err
 	}
 
 	return nil
 }
 
-// Close stops the OSC server and closes the connection.
-func (self *Server) Close() error {
-	if self.conn == nil {
+// connectStream opens the server's stream listener (TCP, TCP+SLIP or a
+// Unix SOCK_STREAM socket).
+func (self *Server) connectStream() error {
+	if self.scheme == schemeUnixStream {
+		if err := removeStaleUnixSocket(self.Address); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen(self.scheme.network(), self.Address)
+	if err != nil {
+		return err
+	}
+	self.listener = listener
+
+	if self.scheme == schemeUnixStream {
+		self.unixPath = self.Address
+		if err := self.chmodSocket(); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// chmodSocket applies SocketMode to the just-created Unix domain socket
+// file, if one was configured.
+func (self *Server) chmodSocket() error {
+	if self.socketMode == 0 {
 		return nil
 	}
-	return self.conn.Close()
+	return os.Chmod(self.unixPath, self.socketMode)
+}
+
+// removeStaleUnixSocket removes a Unix domain socket file left behind by
+// a previous, uncleanly terminated server, so ListenUnix(gram) can bind
+// path again.
+func removeStaleUnixSocket(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Close stops the OSC server, closes the connection and, for the Unix
+// schemes, removes the socket file. It returns ErrPrematureClose if
+// called before Listen or Serve ever set up a connection.
+func (self *Server) Close() error {
+	if self.conn == nil && self.listener == nil {
+		return ErrPrematureClose
+	}
+
+	var err error
+	if self.conn != nil {
+		err = self.conn.Close()
+	}
+	if self.listener != nil {
+		if lerr := self.listener.Close(); err == nil {
+			err = lerr
+		}
+	}
+	if self.unixPath != "" {
+		if rerr := os.Remove(self.unixPath); rerr != nil && !os.IsNotExist(rerr) && err == nil {
+			err = rerr
+		}
+	}
+	if self.scheduler != nil {
+		self.scheduler.Close()
+	}
+	return err
+}
+
+// routePacket schedules packet (and, recursively, the contents of any
+// bundle it contains) for dispatch at the deadline implied by its time
+// tags, applying the server's clock skew and late policy.
+func (self *Server) routePacket(packet Packet) {
+	now := time.Now()
+	for _, entry := range flattenPacket(packet, now, false, self.clockSkew) {
+		if entry.explicit && entry.at.Before(now) && self.latePolicy == DropLate {
+			continue
+		}
+		self.scheduler.Schedule(entry.at, entry.packet)
+	}
 }
 
 // AddMsgHandler registers a new message handler function for an OSC address. The handler
@@ -74,9 +273,22 @@ func (self *Server) AddMsgHandler(address string, handler HandlerFunc) error {
 	return self.dispatcher.AddMsgHandler(address, handler)
 }
 
-// ListenAndServe retrieves incoming OSC packets and dispatches the retrieved OSC packets.
+// ListenAndDispatch retrieves incoming OSC packets and dispatches the
+// retrieved OSC packets. It is a thin wrapper around Serve using a
+// context that is never canceled; call Serve directly for graceful
+// shutdown.
 func (self *Server) ListenAndDispatch() error {
-	if self.running {
+	return self.Serve(context.Background())
+}
+
+// Serve listens for incoming OSC packets and dispatches them until ctx is
+// canceled or an unrecoverable read error occurs. On cancellation it
+// closes the underlying connection to unblock the read loop, waits for
+// any in-flight stream handlers and the scheduler's in-flight dispatch to
+// finish, and returns ctx.Err(); the first non-cancellation error is
+// returned otherwise.
+func (self *Server) Serve(ctx context.Context) error {
+	if self.start() {
 		return ErrAlreadyRunning
 	}
 
@@ -88,6 +300,41 @@ func (self *Server) ListenAndDispatch() error {
 		return err
 	}
 
+	self.scheduler = newScheduler(self.dispatcher.Dispatch)
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			self.Close()
+		case <-stopped:
+		}
+	}()
+
+	var err error
+	if self.scheme.isDatagram() {
+		err = self.serveDatagram()
+	} else {
+		self.markReady()
+		err = self.serveStream()
+	}
+
+	// The read loop above only returns once the connection/listener is
+	// closed; block here until the scheduler has likewise quiesced so no
+	// dispatch is still in flight when Serve returns. Safe to call even if
+	// the ctx-watcher goroutine already closed it.
+	self.scheduler.Close()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// serveDatagram reads and routes packets from self.conn (UDP or a Unix
+// SOCK_DGRAM socket) until it is closed.
+func (self *Server) serveDatagram() error {
 	// Set read timeout
 	if self.readTimeout != 0 {
 		if err := self.conn.SetReadDeadline(time.Now().Add(self.readTimeout)); err != nil {
@@ -95,18 +342,59 @@ func (self *Server) ListenAndDispatch() error {
 		}
 	}
 
-	self.running = true
-	self.Listening <- struct{}{}
+	self.markReady()
 
-	for self.running {
+	for {
 		msg, err := self.readFromConnection()
 		if err != nil {
 			return err
 		}
-		self.dispatcher.Dispatch(msg)
+		self.routePacket(msg)
 	}
+}
 
-	return nil
+// serveStream accepts connections on self.listener and dispatches the
+// packets framed on each, one goroutine per connection, until Accept
+// fails (typically because the listener was closed).
+func (self *Server) serveStream() error {
+	for {
+		conn, err := self.listener.Accept()
+		if err != nil {
+			self.streamWg.Wait()
+			return err
+		}
+		self.streamWg.Add(1)
+		go self.handleStreamConn(conn)
+	}
+}
+
+// handleStreamConn reads framed packets from conn and routes them through
+// routePacket, same as the datagram path, until conn is closed or a framing
+// error occurs.
+func (self *Server) handleStreamConn(conn net.Conn) {
+	defer self.streamWg.Done()
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		var data []byte
+		var err error
+		if self.scheme == schemeTCPSLIP {
+			data, err = readSLIP(reader)
+		} else {
+			data, err = readLengthPrefixed(reader)
+		}
+		if err != nil {
+			return
+		}
+
+		var start int
+		packet, err := self.readPacket(bufio.NewReader(bytes.NewReader(data)), &start, len(data))
+		if err != nil {
+			continue
+		}
+		self.routePacket(packet)
+	}
 }
 
 // Listen causes the server to start listening for packets.
@@ -117,7 +405,7 @@ func (self *Server) Listen() error {
 		}
 	}
 
-	if self.running {
+	if self.start() {
 		return ErrAlreadyRunning
 	}
 
@@ -126,8 +414,7 @@ func (self *Server) Listen() error {
 		self.conn.SetReadDeadline(time.Now().Add(self.readTimeout))
 	}
 
-	self.running = true
-	self.Listening <- struct{}{}
+	self.markReady()
 
 	return nil
 }
@@ -162,12 +449,19 @@ func (self *Server) readFromConnection() (packet Packet, err error) {
 	if self.conn == nil {
 		return nil, fmt.Errorf("self.conn is nil")
 	}
-	data := make([]byte, 65535)
+	bufSize := maxUDPPacketSize
+	if self.scheme == schemeUnix {
+		bufSize = maxUnixPacketSize
+	}
+	data := make([]byte, bufSize)
 	var n, start int
-	n, _, err = self.conn.ReadFromUDP(data)
+	n, _, err = self.conn.ReadFrom(data)
+	if err != nil {
+		return nil, err
+	}
 	packet, err = self.readPacket(bufio.NewReader(bytes.NewBuffer(data)), &start, n)
 
-	return packet, nil
+	return packet, err
 }
 
 // receivePacket receives an OSC packet from the given reader.
@@ -272,121 +566,195 @@ func (self *Server) readArguments(msg *Message, reader *bufio.Reader, start *int
 	*start += n
 
 	// If the typetag doesn't start with ',', it's not valid
-	if typetags[0] != ',' {
+	if len(typetags) == 0 || typetags[0] != ',' {
 		return ErrInvalidTypeTag
 	}
 
 	// Remove ',' from the type tag
 	typetags = typetags[1:]
 
-	for _, c := range typetags {
+	pos := 0
+	args, err := self.readArgumentValues(typetags, &pos, reader, start)
+	if err != nil {
+		return err
+	}
+	msg.Arguments = args
+
+	return nil
+}
+
+// readArgumentValues reads the argument values described by typetags
+// (with the leading ',' already stripped) starting at *pos, returning
+// them in wire order. It recurses on '[' and returns on ']' so that
+// array-bracketed arguments are collected into a nested []interface{}.
+func (self *Server) readArgumentValues(typetags string, pos *int, reader *bufio.Reader, start *int) ([]interface{}, error) {
+	var args []interface{}
+
+	for *pos < len(typetags) {
+		c := typetags[*pos]
+		*pos++
+
 		switch c {
 		default:
-			return fmt.Errorf("Unsupported type tag: %c", c)
+			return nil, fmt.Errorf("Unsupported type tag: %c", c)
+
+		// Start of a nested array; collect until the matching ']'.
+		case '[':
+			nested, err := self.readArgumentValues(typetags, pos, reader, start)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, nested)
+
+		// End of a nested array.
+		case ']':
+			return args, nil
 
 		// int32
 		case 'i':
 			var i int32
-			if err = binary.Read(reader, binary.BigEndian, &i); err != nil {
-				return err
+			if err := binary.Read(reader, binary.BigEndian, &i); err != nil {
+				return nil, err
 			}
 			*start += 4
-			msg.Append(i)
+			args = append(args, i)
 
 		// int64
 		case 'h':
 			var i int64
-			if err = binary.Read(reader, binary.BigEndian, &i); err != nil {
-				return err
+			if err := binary.Read(reader, binary.BigEndian, &i); err != nil {
+				return nil, err
 			}
 			*start += 8
-			msg.Append(i)
+			args = append(args, i)
 
 		// float32
 		case 'f':
 			var f float32
-			if err = binary.Read(reader, binary.BigEndian, &f); err != nil {
-				return err
+			if err := binary.Read(reader, binary.BigEndian, &f); err != nil {
+				return nil, err
 			}
 			*start += 4
-			msg.Append(f)
+			args = append(args, f)
 
 		// float64/double
 		case 'd':
 			var d float64
-			if err = binary.Read(reader, binary.BigEndian, &d); err != nil {
-				return err
+			if err := binary.Read(reader, binary.BigEndian, &d); err != nil {
+				return nil, err
 			}
 			*start += 8
-			msg.Append(d)
+			args = append(args, d)
 
 		// string
 		case 's':
-			// TODO: fix reading string value
-			var s string
-			if s, _, err = readPaddedString(reader); err != nil {
-				return err
+			s, n, err := readPaddedString(reader)
+			if err != nil {
+				return nil, err
 			}
-			*start += len(s) + padBytesNeeded(len(s))
-			msg.Append(s)
+			*start += n
+			args = append(args, s)
+
+		// alternate string ('symbol')
+		case 'S':
+			s, n, err := readPaddedString(reader)
+			if err != nil {
+				return nil, err
+			}
+			*start += n
+			args = append(args, Symbol(s))
 
 		// blob
 		case 'b':
-			var buf []byte
-			var n int
-			if buf, n, err = readBlob(reader); err != nil {
-				return err
+			buf, n, err := readBlob(reader)
+			if err != nil {
+				return nil, err
 			}
 			*start += n
-			msg.Append(buf)
+			args = append(args, buf)
 
 		// OSC Time Tag
 		case 't':
 			var tt uint64
-			if err = binary.Read(reader, binary.BigEndian, &tt); err != nil {
-				return nil
+			if err := binary.Read(reader, binary.BigEndian, &tt); err != nil {
+				return nil, err
 			}
 			*start += 8
-			msg.Append(Timetag(tt))
+			args = append(args, Timetag(tt))
+
+		// ASCII char
+		case 'c':
+			var v int32
+			if err := binary.Read(reader, binary.BigEndian, &v); err != nil {
+				return nil, err
+			}
+			*start += 4
+			args = append(args, Char(v))
+
+		// RGBA color
+		case 'r':
+			var buf [4]byte
+			if _, err := io.ReadFull(reader, buf[:]); err != nil {
+				return nil, err
+			}
+			*start += 4
+			args = append(args, RGBA{buf[0], buf[1], buf[2], buf[3]})
+
+		// MIDI message
+		case 'm':
+			var buf [4]byte
+			if _, err := io.ReadFull(reader, buf[:]); err != nil {
+				return nil, err
+			}
+			*start += 4
+			args = append(args, MIDI{buf[0], buf[1], buf[2], buf[3]})
+
+		// Nil
+		case 'N':
+			args = append(args, Nil{})
+
+		// Impulse/Infinitum
+		case 'I':
+			args = append(args, Impulse{})
 
 		// True
 		case 'T':
-			msg.Append(true)
+			args = append(args, true)
 
 		// False
 		case 'F':
-			msg.Append(false)
+			args = append(args, false)
 		}
 	}
 
-	return nil
+	return args, nil
 }
 
 // readBlob reads an OSC Blob from the blob byte array. Padding bytes are removed
 // from the reader and not returned.
 func readBlob(reader *bufio.Reader) (blob []byte, n int, err error) {
 	// First, get the length
-	var blobLen int
+	var blobLen int32
 	if err = binary.Read(reader, binary.BigEndian, &blobLen); err != nil {
 		return nil, 0, err
 	}
-	n = 4 + blobLen
+	n = 4 + int(blobLen)
 
 	// Read the data
 	blob = make([]byte, blobLen)
-	if _, err = reader.Read(blob); err != nil {
+	if _, err = io.ReadFull(reader, blob); err != nil {
 		return nil, 0, err
 	}
 
 	// Remove the padding bytes
-	numPadBytes := padBytesNeeded(blobLen)
+	numPadBytes := padBytesNeeded(int(blobLen))
 	if numPadBytes > 0 {
 		n += numPadBytes
 		dummy := make([]byte, numPadBytes)
-		if _, err = reader.Read(dummy); err != nil {
+		if _, err = io.ReadFull(reader, dummy); err != nil {
 			return nil, 0, err
 		}
 	}
 
 	return blob, n, nil
-}
\ No newline at end of file
+}
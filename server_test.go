@@ -1,6 +1,10 @@
 package osc
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+)
 
 func TestAddMsgHandler(t *testing.T) {
 	server, err := NewServer("localhost:6677")
@@ -14,6 +18,18 @@ func TestAddMsgHandler(t *testing.T) {
 	}
 }
 
+func TestAddMsgHandlerWithPatternAddress(t *testing.T) {
+	server, err := NewServer("localhost:6677")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = server.Close() }() // Best effort.
+
+	if err := server.AddMsgHandler("/address*/test", func(msg *Message) {}); err != nil {
+		t.Errorf("Expected that OSC pattern '/address*/test' is valid: %v", err)
+	}
+}
+
 func TestAddMsgHandlerWithInvalidAddress(t *testing.T) {
 	server, err := NewServer("localhost:6677")
 	if err != nil {
@@ -21,8 +37,8 @@ func TestAddMsgHandlerWithInvalidAddress(t *testing.T) {
 	}
 	defer func() { _ = server.Close() }() // Best effort.
 
-	if err := server.AddMsgHandler("/address*/test", func(msg *Message) {}); err == nil {
-		t.Error("Expected error with '/address*/test'")
+	if err := server.AddMsgHandler("address/test", func(msg *Message) {}); err == nil {
+		t.Error("Expected error with 'address/test'")
 	}
 }
 
@@ -35,11 +51,11 @@ func TestServerMessageDispatching(t *testing.T) {
 
 	if err := server.AddMsgHandler("/address/test", func(msg *Message) {
 		if len(msg.Arguments) != 1 {
-			t.Error("Argument length should be 1 and is: " + string(len(msg.Arguments)))
+			t.Errorf("Argument length should be 1 and is: %d", len(msg.Arguments))
 		}
 
 		if msg.Arguments[0].(int32) != 1122 {
-			t.Error("Argument should be 1122 and is: " + string(msg.Arguments[0].(int32)))
+			t.Errorf("Argument should be 1122 and is: %d", msg.Arguments[0].(int32))
 		}
 	}); err != nil {
 		t.Error("Error adding message handler")
@@ -57,7 +73,7 @@ func TestServerMessageDispatching(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-	case <-server.Listening:
+	case <-server.Ready():
 		client := NewClient("localhost:6677")
 		msg := NewMessage("/address/test")
 		msg.Append(int32(1122))
@@ -65,6 +81,98 @@ func TestServerMessageDispatching(t *testing.T) {
 	}
 }
 
+func TestServerBundleDispatchedAtTimetag(t *testing.T) {
+	server, err := NewServer("localhost:6678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = server.Close() }() // Best effort.
+
+	received := make(chan time.Time, 1)
+	if err := server.AddMsgHandler("/address/test", func(msg *Message) {
+		received <- time.Now()
+	}); err != nil {
+		t.Fatal("Error adding message handler")
+	}
+
+	errChan := make(chan error)
+
+	// Start the OSC server in a new go-routine
+	go func() {
+		errChan <- server.ListenAndDispatch()
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-server.Ready():
+		sent := time.Now()
+		deadline := sent.Add(200 * time.Millisecond)
+
+		client := NewClient("localhost:6678")
+		bundle := NewBundle(deadline)
+		bundle.Append(NewMessage("/address/test"))
+		if err := client.Send(bundle); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case dispatched := <-received:
+			if delta := dispatched.Sub(deadline); delta < -20*time.Millisecond || delta > 200*time.Millisecond {
+				t.Errorf("dispatched %v from the bundle's time tag, want within tolerance", delta)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("bundle was never dispatched")
+		}
+	}
+}
+
+func TestServeStopsOnContextCancel(t *testing.T) {
+	server, err := NewServer("localhost:6679")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Serve(ctx)
+	}()
+
+	<-server.Ready()
+	cancel()
+
+	select {
+	case err := <-errChan:
+		if err != context.Canceled {
+			t.Errorf("got error %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Serve did not return after context cancellation")
+	}
+}
+
+func TestServeReturnsErrAlreadyRunning(t *testing.T) {
+	server, err := NewServer("localhost:6680")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = server.Close() }() // Best effort.
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = server.Serve(ctx) }()
+	<-server.Ready()
+
+	if err := server.Serve(ctx); err != ErrAlreadyRunning {
+		t.Errorf("got error %v, want ErrAlreadyRunning", err)
+	}
+}
+
 func TestServerIsNotRunningAndGetsClosed(t *testing.T) {
 	server, err := NewServer("127.0.0.1:8000")
 	if err != nil {
@@ -0,0 +1,161 @@
+package osc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// scheme identifies the wire transport and framing used by a Server or
+// Client address, e.g. "udp://", "tcp://" or "tcp+slip://".
+type scheme string
+
+const (
+	schemeUDP        scheme = "udp"
+	schemeTCP        scheme = "tcp"         // stream + 4-byte length-prefix framing (OSC 1.1)
+	schemeTCPSLIP    scheme = "tcp+slip"    // stream + SLIP framing (OSC 1.1 / RFC 1055)
+	schemeUnix       scheme = "unix"        // SOCK_DGRAM Unix domain socket; preserves packet boundaries like UDP
+	schemeUnixStream scheme = "unix+stream" // SOCK_STREAM Unix domain socket + 4-byte length-prefix framing
+)
+
+// splitScheme parses addr into a scheme and the remaining address. An
+// address with no "scheme://" prefix defaults to schemeUDP.
+func splitScheme(addr string) (scheme, string, error) {
+	s, hostport := schemeUDP, addr
+	if i := strings.Index(addr, "://"); i >= 0 {
+		s, hostport = scheme(addr[:i]), addr[i+3:]
+	}
+	switch s {
+	case schemeUDP, schemeTCP, schemeTCPSLIP, schemeUnix, schemeUnixStream:
+		return s, hostport, nil
+	default:
+		return "", "", fmt.Errorf("osc: unsupported scheme %q", s)
+	}
+}
+
+// network returns the net.Dial/net.Listen network name for s.
+func (s scheme) network() string {
+	switch s {
+	case schemeUDP:
+		return "udp"
+	case schemeUnix:
+		return "unixgram"
+	case schemeUnixStream:
+		return "unix"
+	default:
+		return "tcp"
+	}
+}
+
+// isDatagram reports whether s is a packet-oriented transport (UDP or a
+// Unix SOCK_DGRAM socket), as opposed to a stream transport that needs
+// framing and an Accept loop.
+func (s scheme) isDatagram() bool {
+	return s == schemeUDP || s == schemeUnix
+}
+
+// SLIP special bytes, per RFC 1055.
+const (
+	slipEnd    byte = 0xC0
+	slipEsc    byte = 0xDB
+	slipEscEnd byte = 0xDC
+	slipEscEsc byte = 0xDD
+)
+
+// writeLengthPrefixed writes data to w preceded by its 4-byte big-endian
+// length, per the OSC 1.1 stream encoding.
+func writeLengthPrefixed(w io.Writer, data []byte) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// maxLengthPrefixedPacketSize bounds the length prefix read by
+// readLengthPrefixed, so a hostile or corrupt peer can't make the server
+// allocate an arbitrarily large buffer (the prefix is a 4-byte field and
+// could otherwise claim up to ~4 GiB).
+const maxLengthPrefixedPacketSize = 16 << 20 // 16 MiB
+
+// readLengthPrefixed reads one length-prefixed OSC packet from r.
+func readLengthPrefixed(r io.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	if n > maxLengthPrefixedPacketSize {
+		return nil, fmt.Errorf("osc: length-prefixed packet of %d bytes exceeds maximum of %d", n, maxLengthPrefixedPacketSize)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// writeSLIP writes data to w as a single SLIP-framed (RFC 1055) packet,
+// terminated by an END byte.
+func writeSLIP(w io.Writer, data []byte) error {
+	out := make([]byte, 0, len(data)+2)
+	for _, b := range data {
+		switch b {
+		case slipEnd:
+			out = append(out, slipEsc, slipEscEnd)
+		case slipEsc:
+			out = append(out, slipEsc, slipEscEsc)
+		default:
+			out = append(out, b)
+		}
+	}
+	out = append(out, slipEnd)
+	_, err := w.Write(out)
+	return err
+}
+
+// readSLIP reads one SLIP-framed packet from r. Leading END bytes, which
+// senders may emit to flush garbage accumulated on the line, are skipped.
+func readSLIP(r *bufio.Reader) ([]byte, error) {
+	var b byte
+	var err error
+	for {
+		b, err = r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != slipEnd {
+			break
+		}
+	}
+
+	var out []byte
+	for {
+		if b == slipEnd {
+			return out, nil
+		}
+		if b == slipEsc {
+			esc, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			switch esc {
+			case slipEscEnd:
+				out = append(out, slipEnd)
+			case slipEscEsc:
+				out = append(out, slipEsc)
+			default:
+				return nil, errors.New("osc: invalid SLIP escape sequence")
+			}
+		} else {
+			out = append(out, b)
+		}
+
+		b, err = r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
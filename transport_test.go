@@ -0,0 +1,163 @@
+package osc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testRoundTrip(t *testing.T, addr string) {
+	server, err := NewServer(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = server.Close() }() // Best effort.
+
+	received := make(chan *Message, 2)
+	if err := server.AddMsgHandler("/round/trip", func(msg *Message) {
+		received <- msg
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- server.ListenAndDispatch() }()
+
+	select {
+	case err := <-errChan:
+		t.Fatal(err)
+	case <-server.Ready():
+	}
+
+	client := NewClient(addr)
+	defer func() { _ = client.Close() }() // Best effort.
+
+	msg := NewMessage("/round/trip")
+	msg.Append(int32(42), "hello")
+	if err := client.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := NewBundle(time.Time{})
+	bundled := NewMessage("/round/trip")
+	bundled.Append(float32(1.5))
+	bundle.Append(bundled)
+	if err := client.Send(bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message to be dispatched")
+		}
+	}
+}
+
+func TestTCPRoundTrip(t *testing.T) {
+	testRoundTrip(t, "tcp://127.0.0.1:6681")
+}
+
+func TestTCPSlipRoundTrip(t *testing.T) {
+	testRoundTrip(t, "tcp+slip://127.0.0.1:6682")
+}
+
+func TestUnixgramRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osc.sock")
+	testRoundTrip(t, fmt.Sprintf("unix://%s", path))
+}
+
+func TestUnixStreamRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osc.sock")
+	testRoundTrip(t, fmt.Sprintf("unix+stream://%s", path))
+}
+
+func TestUnixgramSocketFileLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osc.sock")
+
+	server, err := NewServer(fmt.Sprintf("unix://%s", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetSocketMode(0600)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- server.ListenAndDispatch() }()
+
+	select {
+	case err := <-errChan:
+		t.Fatal(err)
+	case <-server.Ready():
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected socket file to exist: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("got socket mode %v, want 0600", perm)
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected socket file to be removed on Close, got err %v", err)
+	}
+}
+
+func TestUnixgramRemovesStaleSocketFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osc.sock")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(fmt.Sprintf("unix://%s", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = server.Close() }() // Best effort.
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- server.ListenAndDispatch() }()
+
+	select {
+	case err := <-errChan:
+		t.Fatal(err)
+	case <-server.Ready():
+	}
+}
+
+func TestSLIPFraming(t *testing.T) {
+	data := []byte{0x00, slipEnd, 0x01, slipEsc, 0x02}
+
+	var buf bytes.Buffer
+	if err := writeSLIP(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readSLIP(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("got %v, want %v", got, data)
+	}
+}
+
+func TestReadLengthPrefixedRejectsOversizedPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, uint32(maxLengthPrefixedPacketSize+1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readLengthPrefixed(&buf); err == nil {
+		t.Error("expected an error for a length prefix over the maximum")
+	}
+}
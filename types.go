@@ -0,0 +1,28 @@
+package osc
+
+// Nil is the OSC 'N' argument: an explicit nil value with no payload
+// bytes on the wire.
+type Nil struct{}
+
+// Impulse is the OSC 'I' argument (also known as Infinitum or "bang"):
+// an impulse value with no payload bytes on the wire.
+type Impulse struct{}
+
+// Char is the OSC 'c' argument: an ASCII character, sent as a 32-bit
+// big-endian integer.
+type Char rune
+
+// RGBA is the OSC 'r' argument: a 32-bit RGBA color.
+type RGBA struct {
+	R, G, B, A byte
+}
+
+// MIDI is the OSC 'm' argument: a 4-byte MIDI message consisting of port
+// ID, status byte and two data bytes.
+type MIDI struct {
+	PortID, Status, Data1, Data2 byte
+}
+
+// Symbol is the OSC 'S' argument: an alternate string type with the same
+// wire format as a regular OSC string ('s').
+type Symbol string
@@ -0,0 +1,80 @@
+package osc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"time"
+)
+
+// BundleTag is the OSC string that prefixes every bundle on the wire.
+const BundleTag = "#bundle"
+
+// secondsFrom1900To1970 is the offset between the NTP and Unix epochs.
+const secondsFrom1900To1970 = 2208988800
+
+// Timetag is an OSC time tag: a 64-bit NTP timestamp (32-bit seconds since
+// 1900-01-01, 32-bit fraction of a second).
+type Timetag uint64
+
+// padBytesNeeded returns the number of padding bytes required to align n
+// bytes to the next 4-byte boundary, per the OSC string/blob padding rule.
+func padBytesNeeded(n int) int {
+	pad := 4 - n%4
+	if pad == 4 {
+		return 0
+	}
+	return pad
+}
+
+// readPaddedString reads a null-terminated, 4-byte-aligned OSC string from
+// reader and returns the string together with the total number of bytes
+// consumed, including the terminator and any padding.
+func readPaddedString(reader *bufio.Reader) (str string, n int, err error) {
+	raw, err := reader.ReadBytes(0)
+	if err != nil {
+		return "", 0, err
+	}
+	n = len(raw)
+	if pad := padBytesNeeded(n); pad > 0 {
+		if _, err := io.CopyN(io.Discard, reader, int64(pad)); err != nil {
+			return "", 0, err
+		}
+		n += pad
+	}
+	return string(raw[:len(raw)-1]), n, nil
+}
+
+// writePaddedString writes str to buf as a null-terminated, 4-byte-aligned
+// OSC string and returns the number of bytes written.
+func writePaddedString(str string, buf *bytes.Buffer) (int, error) {
+	data := append([]byte(str), 0)
+	if pad := padBytesNeeded(len(data)); pad > 0 {
+		data = append(data, make([]byte, pad)...)
+	}
+	return buf.Write(data)
+}
+
+// timetagToTime converts an OSC/NTP time tag into a time.Time. The special
+// value 1 (seconds=0, fraction=1) means "immediately" and is returned as
+// the zero time.Time rather than 1900-01-01.
+func timetagToTime(tt uint64) time.Time {
+	if tt == 1 {
+		return time.Time{}
+	}
+	seconds := int64(tt >> 32)
+	frac := tt & 0xffffffff
+	t := time.Unix(seconds-secondsFrom1900To1970, 0)
+	return t.Add(time.Duration(float64(frac) / (1 << 32) * float64(time.Second)))
+}
+
+// timeToTimetag converts a time.Time into an OSC/NTP time tag. The zero
+// time.Time is encoded as 1, the "immediately" time tag.
+func timeToTimetag(t time.Time) uint64 {
+	if t.IsZero() {
+		return 1
+	}
+	seconds := uint64(t.Unix() + secondsFrom1900To1970)
+	frac := uint64((float64(t.Nanosecond()) / 1e9) * (1 << 32))
+	return seconds<<32 | frac
+}